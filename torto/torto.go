@@ -2,14 +2,24 @@ package torto
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -18,16 +28,126 @@ import (
 type Config struct {
 	target                 string
 	argReplacements        map[string]string
+	argsMu                 sync.Mutex
 	debug                  bool
 	force                  bool
+	always                 bool
+	why                    string
+	printConfig            bool
+	stdin                  bool
+	stdinFilename          string
+	jobs                   int
 	targetsFilePath        string
 	defaultTargetsFilePath string
-	thisTarget             []string
+}
+
+func (c *Config) setVar(name, value string) {
+	c.argsMu.Lock()
+	defer c.argsMu.Unlock()
+	c.argReplacements[name] = value
+}
+
+// Command is a single shell command within a target. In the long form it
+// can pipe a resolved value into the process's stdin, e.g. to consume the
+// output captured from another target.
+type Command struct {
+	Cmd   string
+	Stdin string
+}
+
+func (cmd *Command) UnmarshalYAML(value *yaml.Node) error {
+	var shorthand string
+	if err := value.Decode(&shorthand); err == nil {
+		cmd.Cmd = shorthand
+		return nil
+	}
+
+	var longForm struct {
+		Cmd   string `yaml:"cmd"`
+		Stdin string `yaml:"stdin"`
+	}
+
+	if err := value.Decode(&longForm); err != nil {
+		return err
+	}
+
+	cmd.Cmd = longForm.Cmd
+	cmd.Stdin = longForm.Stdin
+
+	return nil
+}
+
+type Target struct {
+	Commands    []Command
+	Deps        []string
+	Cwd         string
+	Env         map[string]string
+	Shell       string
+	Timeout     time.Duration
+	Silent      bool
+	Description string
+	Sources     []string
+	Outputs     []string
+	Hash        *bool
+	Capture     string
+}
+
+func (t *Target) UnmarshalYAML(value *yaml.Node) error {
+	var shorthand []string
+	if err := value.Decode(&shorthand); err == nil {
+		t.Commands = make([]Command, len(shorthand))
+		for i, command := range shorthand {
+			t.Commands[i] = Command{Cmd: command}
+		}
+		return nil
+	}
+
+	var longForm struct {
+		Commands    []Command         `yaml:"commands"`
+		Deps        []string          `yaml:"deps"`
+		Cwd         string            `yaml:"cwd"`
+		Env         map[string]string `yaml:"env"`
+		Shell       string            `yaml:"shell"`
+		Timeout     string            `yaml:"timeout"`
+		Silent      bool              `yaml:"silent"`
+		Description string            `yaml:"description"`
+		Sources     []string          `yaml:"sources"`
+		Outputs     []string          `yaml:"outputs"`
+		Hash        *bool             `yaml:"hash"`
+		Capture     string            `yaml:"capture"`
+	}
+
+	if err := value.Decode(&longForm); err != nil {
+		return err
+	}
+
+	t.Commands = longForm.Commands
+	t.Deps = longForm.Deps
+	t.Cwd = longForm.Cwd
+	t.Env = longForm.Env
+	t.Shell = longForm.Shell
+	t.Silent = longForm.Silent
+	t.Description = longForm.Description
+	t.Sources = longForm.Sources
+	t.Outputs = longForm.Outputs
+	t.Hash = longForm.Hash
+	t.Capture = longForm.Capture
+
+	if longForm.Timeout != "" {
+		d, err := time.ParseDuration(longForm.Timeout)
+		if err != nil {
+			return err
+		}
+		t.Timeout = d
+	}
+
+	return nil
 }
 
 type Targets struct {
-	Targets map[string][]string `yaml:"targets"`
-	Vars    map[string]string   `yaml:"vars"`
+	Targets map[string]Target `yaml:"targets"`
+	Vars    map[string]string `yaml:"vars"`
+	Include []string          `yaml:"include"`
 }
 
 func dbgln(c *Config, format string, a ...any) {
@@ -36,29 +156,109 @@ func dbgln(c *Config, format string, a ...any) {
 	}
 }
 
-func getTargetsFile(filename string) (*Targets, error) {
-	jsonFile, err := os.Open(filename)
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// visitKey returns the key used to detect circular includes: the URL
+// itself for remote includes, or the absolute path for local ones, so the
+// same file referenced two different ways is still recognised as the same
+// node.
+func visitKey(path string) (string, error) {
+	if isURL(path) {
+		return path, nil
+	}
+	return filepath.Abs(path)
+}
+
+func readTargetsSource(path string) ([]byte, error) {
+	if isURL(path) {
+		resp, err := http.Get(path)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s: unexpected status %s", path, resp.Status)
+		}
 
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	jsonFile, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer jsonFile.Close()
 
-	byteValue, _ := ioutil.ReadAll(jsonFile)
+	return ioutil.ReadAll(jsonFile)
+}
 
-	var targets Targets
+func mergeTargetsInto(dst *Targets, src *Targets) {
+	for k, v := range src.Targets {
+		dst.Targets[k] = v
+	}
+	for k, v := range src.Vars {
+		dst.Vars[k] = v
+	}
+}
+
+// loadTargetsFile reads path (a local file or an https:// URL), merges in
+// whatever it lists under `include:` (earlier includes overridden by later
+// ones, and both overridden by path's own targets/vars), and fails with a
+// clear error if path is already in visited.
+func loadTargetsFile(path string, visited map[string]bool) (*Targets, error) {
+	key, err := visitKey(path)
+	if err != nil {
+		return nil, err
+	}
 
-	err = yaml.Unmarshal(byteValue, &targets)
+	if visited[key] {
+		return nil, errors.New("circular include detected at " + path)
+	}
+	visited[key] = true
+	defer delete(visited, key)
 
+	data, err := readTargetsSource(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return &targets, nil
+	var targets Targets
+	if err := yaml.Unmarshal(data, &targets); err != nil {
+		return nil, err
+	}
+
+	merged := emptyTargets()
+
+	baseDir := filepath.Dir(path)
+	for _, include := range targets.Include {
+		includePath := include
+		if !isURL(include) && !filepath.IsAbs(include) {
+			includePath = filepath.Join(baseDir, include)
+		}
+
+		included, err := loadTargetsFile(includePath, visited)
+		if err != nil {
+			return nil, err
+		}
+
+		mergeTargetsInto(merged, included)
+	}
+
+	mergeTargetsInto(merged, &targets)
+
+	return merged, nil
+}
+
+func getTargetsFile(filename string) (*Targets, error) {
+	return loadTargetsFile(filename, make(map[string]bool))
 }
 
 func emptyTargets() *Targets {
 	return &Targets{
-		Targets: make(map[string][]string),
+		Targets: make(map[string]Target),
 		Vars:    make(map[string]string),
 	}
 }
@@ -79,12 +279,7 @@ func getTargets(c *Config) (*Targets, error) {
 		thisDirTargets = emptyTargets()
 	}
 
-	for k, v := range thisDirTargets.Targets {
-		defaultTargets.Targets[k] = v
-	}
-	for k, v := range thisDirTargets.Vars {
-		defaultTargets.Vars[k] = v
-	}
+	mergeTargetsInto(defaultTargets, thisDirTargets)
 
 	for k, v := range defaultTargets.Vars {
 		c.argReplacements[k] = v
@@ -134,30 +329,269 @@ func getTargetNameAndRunArgs(programArgs []string) (string, map[string]string, e
 }
 
 func withResolvedArgs(argValue string, c *Config) string {
+	c.argsMu.Lock()
+	vars := make(map[string]string, len(c.argReplacements))
 	for k, v := range c.argReplacements {
+		vars[k] = v
+	}
+	c.argsMu.Unlock()
+
+	return resolveFromVars(argValue, vars)
+}
+
+// resolveFromVars is the recursive step of withResolvedArgs, working off of
+// a private snapshot of the variables so it's safe to call while other
+// targets may be writing captured output into the shared Config.
+func resolveFromVars(argValue string, vars map[string]string) string {
+	for k, v := range vars {
 		if strings.Contains(argValue, "$"+k) {
-			resolvedArgValue := withResolvedArgs(v, c)
+			resolvedArgValue := resolveFromVars(v, vars)
 			argValue = strings.ReplaceAll(argValue, "$"+k, resolvedArgValue)
 		}
 	}
 	return argValue
 }
 
-func Execute(c *Config) error {
-	var executor string
-	if runtime.GOOS == "windows" {
-		executor = "powershell"
-	} else {
-		executor = "sh"
+// graphNode is a target plus the bookkeeping needed to schedule it once all
+// of its dependencies have completed.
+type graphNode struct {
+	name       string
+	target     Target
+	dependents []string
+}
+
+// buildGraph walks the dependency closure of root and returns every node
+// reachable from it, failing with a readable error if a dependency is
+// missing or a cycle is found.
+func buildGraph(targets *Targets, root string) (map[string]*graphNode, error) {
+	nodes := make(map[string]*graphNode)
+
+	// inProgress is the current DFS path (a "gray" set); done is every node
+	// whose subtree has already fully resolved. A back-edge into inProgress
+	// is a cycle; a back-edge into done is just a shared dependency (e.g. a
+	// diamond) and can be memoized.
+	inProgress := make(map[string]bool)
+	done := make(map[string]bool)
+
+	var visit func(name string, stack []string) error
+	visit = func(name string, stack []string) error {
+		if done[name] {
+			return nil
+		}
+
+		if inProgress[name] {
+			cycle := append(append([]string{}, stack...), name)
+			return errors.New("dependency cycle detected: " + strings.Join(cycle, " -> "))
+		}
+
+		t, ok := targets.Targets[name]
+		if !ok {
+			return errors.New("target " + name + " does not exist")
+		}
+
+		inProgress[name] = true
+		defer delete(inProgress, name)
+
+		nodes[name] = &graphNode{name: name, target: t}
+
+		for _, dep := range t.Deps {
+			if err := visit(dep, append(stack, name)); err != nil {
+				return err
+			}
+			nodes[dep].dependents = append(nodes[dep].dependents, name)
+		}
+
+		done[name] = true
+
+		return nil
 	}
 
-	configYml, _ := yaml.Marshal(&c)
-	dbgln(c, "%s", string(configYml))
+	if err := visit(root, nil); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+const cacheDir = ".torto"
+const cacheFile = cacheDir + "/cache.json"
 
-	for _, v := range c.thisTarget {
-		command := withResolvedArgs(v, c)
+// buildCache persists the sha256 hashes of a target's matched source files,
+// keyed by target name, so unchanged-but-touched files don't trigger a
+// rebuild on the next run.
+type buildCache struct {
+	Targets map[string]map[string]string `json:"targets"`
+}
+
+func loadBuildCache() *buildCache {
+	data, err := ioutil.ReadFile(cacheFile)
+	if err != nil {
+		return &buildCache{Targets: make(map[string]map[string]string)}
+	}
+
+	var c buildCache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return &buildCache{Targets: make(map[string]map[string]string)}
+	}
+
+	if c.Targets == nil {
+		c.Targets = make(map[string]map[string]string)
+	}
+
+	return &c
+}
+
+func (c *buildCache) save() error {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(cacheFile, data, 0644)
+}
+
+func matchGlobs(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func hashFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// targetUpToDate reports whether node can be skipped: every declared output
+// exists and is newer than every declared source (or, unless hashing is
+// disabled via `hash: false`, every source's content hash still matches the
+// hash recorded the last time the target ran). It returns the source that
+// forced a rebuild for --why reporting.
+func targetUpToDate(c *Config, node *graphNode, cache *buildCache) (bool, string) {
+	if c.always {
+		return false, ""
+	}
+
+	if len(node.target.Sources) == 0 || len(node.target.Outputs) == 0 {
+		return false, ""
+	}
+
+	sources, err := matchGlobs(node.target.Sources)
+	if err != nil || len(sources) == 0 {
+		return false, ""
+	}
+
+	outputs, err := matchGlobs(node.target.Outputs)
+	if err != nil || len(outputs) == 0 {
+		return false, "missing outputs"
+	}
+
+	useHash := node.target.Hash == nil || *node.target.Hash
+
+	if useHash {
+		prev := cache.Targets[node.name]
+		for _, source := range sources {
+			h, err := hashFile(source)
+			if err != nil || prev == nil || prev[source] != h {
+				return false, source
+			}
+		}
+		return true, ""
+	}
+
+	var newestSource time.Time
+	for _, source := range sources {
+		info, err := os.Stat(source)
+		if err != nil {
+			return false, source
+		}
+		if info.ModTime().After(newestSource) {
+			newestSource = info.ModTime()
+		}
+	}
+
+	for _, output := range outputs {
+		info, err := os.Stat(output)
+		if err != nil || info.ModTime().Before(newestSource) {
+			return false, output
+		}
+	}
+
+	return true, ""
+}
+
+func recordTargetHashes(node *graphNode, cache *buildCache) {
+	useHash := node.target.Hash == nil || *node.target.Hash
+	if !useHash || len(node.target.Sources) == 0 {
+		return
+	}
+
+	sources, err := matchGlobs(node.target.Sources)
+	if err != nil {
+		return
+	}
+
+	hashes := make(map[string]string, len(sources))
+	for _, source := range sources {
+		if h, err := hashFile(source); err == nil {
+			hashes[source] = h
+		}
+	}
+
+	cache.Targets[node.name] = hashes
+}
+
+func runTarget(c *Config, defaultExecutor string, node *graphNode) error {
+	executor := defaultExecutor
+	if node.target.Shell != "" {
+		executor = node.target.Shell
+	}
+
+	ctx := context.Background()
+	if node.target.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, node.target.Timeout)
+		defer cancel()
+	}
+
+	var captured *bytes.Buffer
+	if node.target.Capture != "" {
+		captured = &bytes.Buffer{}
+	}
 
-		cmd := exec.Command(executor, "-c", command)
+	for _, v := range node.target.Commands {
+		command := withResolvedArgs(v.Cmd, c)
+
+		cmd := exec.CommandContext(ctx, executor, "-c", command)
+
+		if node.target.Cwd != "" {
+			cmd.Dir = node.target.Cwd
+		}
+
+		if len(node.target.Env) > 0 {
+			env := os.Environ()
+			for k, envVal := range node.target.Env {
+				env = append(env, k+"="+withResolvedArgs(envVal, c))
+			}
+			cmd.Env = env
+		}
+
+		if v.Stdin != "" {
+			cmd.Stdin = strings.NewReader(withResolvedArgs(v.Stdin, c))
+		}
 
 		if c.debug {
 			fmt.Println(cmd.String())
@@ -166,12 +600,23 @@ func Execute(c *Config) error {
 
 		stderr := &bytes.Buffer{}
 
-		cmd.Stdout = os.Stdout
+		switch {
+		case captured != nil && !node.target.Silent:
+			cmd.Stdout = io.MultiWriter(captured, os.Stdout)
+		case captured != nil:
+			cmd.Stdout = captured
+		case !node.target.Silent:
+			cmd.Stdout = os.Stdout
+		}
 		cmd.Stderr = stderr
 
 		err := cmd.Run()
 
 		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("%s: timed out after %s running '%s'", node.name, node.target.Timeout, command)
+			}
+
 			var f func(a ...any)
 			if strings.HasSuffix(stderr.String(), "\n") {
 				f = func(a ...any) { fmt.Print(a...) }
@@ -179,30 +624,223 @@ func Execute(c *Config) error {
 				f = func(a ...any) { fmt.Println(a...) }
 			}
 
-			f(c.target + ": error executing '" + command + "': " + stderr.String())
-			if !c.force {
-				return err
-			} else {
-				fmt.Println(err)
-			}
+			f(node.name + ": error executing '" + command + "': " + stderr.String())
+			return err
 		}
+	}
 
+	if captured != nil {
+		c.setVar(node.target.Capture, strings.TrimSpace(captured.String()))
 	}
 
 	return nil
 }
 
+// Execute resolves the dependency graph rooted at c.target and runs it with
+// up to c.jobs goroutines, only ever running each target once. Unless
+// --force is set, the first failing target stops the whole run; with
+// --force, the failed branch is marked and every other branch keeps going.
+func Execute(c *Config) error {
+	var executor string
+	if runtime.GOOS == "windows" {
+		executor = "powershell"
+	} else {
+		executor = "sh"
+	}
+
+	configYml, _ := yaml.Marshal(&c)
+	dbgln(c, "%s", string(configYml))
+
+	if c.stdinFilename != "" {
+		c.setVar("STDIN", c.stdinFilename)
+	} else if c.stdin {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		c.setVar("STDIN", string(data))
+	}
+
+	targets, err := getTargets(c)
+	if err != nil {
+		return err
+	}
+
+	if c.printConfig {
+		effectiveYml, err := yaml.Marshal(targets)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(effectiveYml))
+		return nil
+	}
+
+	nodes, err := buildGraph(targets, c.target)
+	if err != nil {
+		return err
+	}
+
+	jobs := c.jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	cache := loadBuildCache()
+	var cacheMu sync.Mutex
+	if !c.debug {
+		// A dry run must have no side effects: don't let it create .torto
+		// or persist hashes for commands it never actually ran.
+		defer cache.save()
+	}
+
+	// remaining/depFailed track each node's readiness; mu guards both
+	// alongside the dependency bookkeeping pulled from nodes.
+	var mu sync.Mutex
+	remaining := make(map[string]int, len(nodes))
+	depFailed := make(map[string]bool, len(nodes))
+	for name, node := range nodes {
+		remaining[name] = len(node.target.Deps)
+	}
+
+	var firstErr error
+	var firstErrOnce sync.Once
+	stopCh := make(chan struct{})
+	var stopOnce sync.Once
+	stop := func() { stopOnce.Do(func() { close(stopCh) }) }
+
+	ready := make(chan string, len(nodes))
+	var pending sync.WaitGroup
+	pending.Add(len(nodes))
+
+	var dispatch func(name string)
+	var completeNode func(name string, isFailure bool, recordHash bool, runErr error)
+
+	// dispatch is called the moment a node's dependencies are all resolved:
+	// it either short-circuits (ancestor failed, or the target is already
+	// up to date) or hands the target to a worker via ready.
+	dispatch = func(name string) {
+		mu.Lock()
+		ancestorFailed := depFailed[name]
+		mu.Unlock()
+
+		if ancestorFailed {
+			completeNode(name, true, false, nil)
+			return
+		}
+
+		node := nodes[name]
+
+		cacheMu.Lock()
+		upToDate, reason := targetUpToDate(c, node, cache)
+		cacheMu.Unlock()
+
+		if upToDate {
+			fmt.Println(name + ": up to date")
+			completeNode(name, false, false, nil)
+			return
+		}
+
+		if reason != "" && name == c.why {
+			fmt.Println(name + ": rebuilding because of " + reason)
+		}
+
+		ready <- name
+	}
+
+	// completeNode records the outcome of a node (run, skipped, or
+	// up-to-date) and cascades to its dependents, enqueuing any that just
+	// became ready.
+	completeNode = func(name string, isFailure bool, recordHash bool, runErr error) {
+		if runErr != nil {
+			firstErrOnce.Do(func() { firstErr = runErr })
+		}
+		if isFailure && !c.force {
+			stop()
+		}
+
+		if recordHash && !c.debug {
+			cacheMu.Lock()
+			recordTargetHashes(nodes[name], cache)
+			cacheMu.Unlock()
+		}
+
+		dependents := nodes[name].dependents
+
+		if isFailure {
+			mu.Lock()
+			for _, dependent := range dependents {
+				depFailed[dependent] = true
+			}
+			mu.Unlock()
+		}
+
+		for _, dependent := range dependents {
+			mu.Lock()
+			remaining[dependent]--
+			ready := remaining[dependent] == 0
+			mu.Unlock()
+
+			if ready {
+				dispatch(dependent)
+			}
+		}
+
+		pending.Done()
+	}
+
+	for name, node := range nodes {
+		if len(node.target.Deps) == 0 {
+			dispatch(name)
+		}
+	}
+
+	go func() {
+		pending.Wait()
+		close(ready)
+	}()
+
+	var workers sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+
+			for name := range ready {
+				select {
+				case <-stopCh:
+					// A prior target already failed without --force:
+					// don't start new work, just unwind bookkeeping.
+					completeNode(name, true, false, nil)
+					continue
+				default:
+				}
+
+				err := runTarget(c, executor, nodes[name])
+				completeNode(name, err != nil, err == nil, err)
+			}
+		}()
+	}
+
+	workers.Wait()
+
+	return firstErr
+}
+
+// CreateCommand builds the torto command tree: `run`, `list`, `show`,
+// `init` and `completion`, with `torto <target>` kept working as an
+// implicit alias for `run` (cobra falls through to the root command
+// whenever the first argument isn't one of the registered subcommands).
 func CreateCommand() *cobra.Command {
 	config := &Config{
 		force:                  false,
+		jobs:                   1,
 		argReplacements:        make(map[string]string),
 		targetsFilePath:        "torto.yml",
 		defaultTargetsFilePath: "~/torto.yml",
 		target:                 "",
-		thisTarget:             []string{},
 	}
 
-	command := &cobra.Command{
+	root := &cobra.Command{
 		Use:          `torto target [args]`,
 		Example:      `torto hello_world VAR1=test"`,
 		Args:         ArgsValidator(config),
@@ -210,16 +848,207 @@ func CreateCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return Execute(config)
 		},
+		ValidArgsFunction: completeTargetNames(config),
+	}
+
+	root.PersistentFlags().BoolVarP(&config.force, "force", "f", false, "run all commands regardless of error")
+	root.PersistentFlags().BoolVarP(&config.debug, "debug", "d", false, "runs in debug mode")
+	root.PersistentFlags().IntVarP(&config.jobs, "jobs", "j", 1, "number of targets to run concurrently")
+	root.PersistentFlags().BoolVar(&config.always, "always", false, "bypass the up-to-date cache and always run targets")
+	root.PersistentFlags().StringVar(&config.why, "why", "", "print which source triggered a rebuild of the given target")
+	root.PersistentFlags().BoolVar(&config.printConfig, "print-config", false, "print the fully merged effective config (after includes) and exit")
+	root.PersistentFlags().BoolVar(&config.stdin, "stdin", false, "read piped stdin into the $STDIN variable")
+	root.PersistentFlags().StringVar(&config.stdinFilename, "stdin-filename", "", "set $STDIN to this file path instead of reading stdin directly")
+
+	root.AddCommand(runCommand(config))
+	root.AddCommand(listCommand(config))
+	root.AddCommand(showCommand(config))
+	root.AddCommand(initCommand(config))
+
+	root.CompletionOptions.DisableDefaultCmd = true
+	root.AddCommand(completionCommand(root))
+
+	return root
+}
+
+func completionCommand(root *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:                   "completion [bash|zsh|fish|powershell]",
+		Short:                 "generate a shell completion script",
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletion(os.Stdout)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(os.Stdout)
+			default:
+				return errors.New("unsupported shell " + args[0])
+			}
+		},
+	}
+}
+
+func runCommand(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:               `run target [args]`,
+		Short:             "run a target",
+		Example:           `torto run hello_world VAR1=test"`,
+		Args:              ArgsValidator(config),
+		SilenceUsage:      true,
+		ValidArgsFunction: completeTargetNames(config),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return Execute(config)
+		},
+	}
+}
+
+func listCommand(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "list the targets available in the merged torto.yml",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runList(config)
+		},
 	}
+}
 
-	command.PersistentFlags().BoolVarP(&config.force, "force", "f", false, "run all commands regardless of error")
-	command.PersistentFlags().BoolVarP(&config.debug, "debug", "d", false, "runs in debug mode")
+func showCommand(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:               `show target [args]`,
+		Short:             "print the fully resolved commands for a target without running them",
+		Args:              cobra.MinimumNArgs(1),
+		SilenceUsage:      true,
+		ValidArgsFunction: completeTargetNames(config),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runShow(config, args)
+		},
+	}
+}
 
-	return command
+func initCommand(config *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:          "init",
+		Short:        "scaffold a starter torto.yml in the current directory",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInit(config)
+		},
+	}
+}
+
+func completeTargetNames(c *Config) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		targets, err := getTargets(c)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		names := make([]string, 0, len(targets.Targets))
+		for name := range targets.Targets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func runList(c *Config) error {
+	targets, err := getTargets(c)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(targets.Targets))
+	for name := range targets.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t := targets.Targets[name]
+
+		line := name
+		if t.Description != "" {
+			line += " - " + t.Description
+		}
+		if len(t.Deps) > 0 {
+			line += " (deps: " + strings.Join(t.Deps, ", ") + ")"
+		}
+
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+func runShow(c *Config, args []string) error {
+	targetName, runArgs, err := getTargetNameAndRunArgs(args)
+	if err != nil {
+		return err
+	}
+
+	c.argReplacements = runArgs
+	c.target = withResolvedArgs(targetName, c)
+
+	targets, err := getTargets(c)
+	if err != nil {
+		return err
+	}
+
+	target, ok := targets.Targets[targetName]
+	if !ok {
+		return errors.New("target " + targetName + " does not exist")
+	}
+
+	for _, v := range target.Commands {
+		line := withResolvedArgs(v.Cmd, c)
+		if v.Stdin != "" {
+			line += " < " + withResolvedArgs(v.Stdin, c)
+		}
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+func runInit(c *Config) error {
+	if _, err := os.Stat(c.targetsFilePath); err == nil {
+		return errors.New(c.targetsFilePath + " already exists")
+	}
+
+	starter := `vars:
+  GREETING: hello world
+
+targets:
+  hello_world:
+    - echo $GREETING
+`
+
+	return ioutil.WriteFile(c.targetsFilePath, []byte(starter), 0644)
 }
 
 func ArgsValidator(c *Config) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		// --print-config only needs the merged targets file, not a valid (or
+		// even present) target name: let it through so a broken include can
+		// be debugged instead of hidden behind a target-validation error.
+		if c.printConfig && len(args) == 0 {
+			return nil
+		}
+
 		targetName, runArgs, err := getTargetNameAndRunArgs(args)
 		if err != nil {
 			return err
@@ -234,14 +1063,13 @@ func ArgsValidator(c *Config) func(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		thisTarget, ok := targets.Targets[targetName]
-
-		if !ok {
+		if _, ok := targets.Targets[targetName]; !ok {
+			if c.printConfig {
+				return nil
+			}
 			return errors.New("target " + targetName + " does not exist")
 		}
 
-		c.thisTarget = thisTarget
-
 		return nil
 	}
 }